@@ -0,0 +1,91 @@
+package cassandra
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/hashicorp/vault/logical"
+	"github.com/hashicorp/vault/logical/framework"
+)
+
+func Factory(conf map[string]string) (logical.Backend, error) {
+	return Backend().Backend, nil
+}
+
+type backend struct {
+	*framework.Backend
+
+	sync.Mutex
+	session *gocql.Session
+}
+
+func Backend() *backend {
+	var b backend
+	b.Backend = &framework.Backend{
+		Help: backendHelp,
+
+		Paths: []*framework.Path{
+			pathConfigConnection(&b),
+			pathListRoles(&b),
+			pathRoles(&b),
+			pathCredsCreate(&b),
+		},
+
+		Secrets: []*framework.Secret{
+			secretCreds(&b),
+		},
+
+		Rollback:       b.secretCredsRollback,
+		RollbackMinAge: 5 * time.Minute,
+	}
+
+	return &b
+}
+
+// DB returns the cached Cassandra session, connecting using the stored
+// config/connection if the cache is cold. s may be nil, in which case only
+// an already-warm cache can be used; this is the case when DB is called
+// from the framework's Rollback callback, which isn't given request
+// storage.
+func (b *backend) DB(s logical.Storage) (*gocql.Session, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.session != nil {
+		return b.session, nil
+	}
+
+	if s == nil {
+		return nil, gocql.ErrNoConnectionsStarted
+	}
+
+	config, err := b.readConnectionConfig(s)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster := gocql.NewCluster(strings.Split(config.Hosts, ",")...)
+	if config.Username != "" {
+		cluster.Authenticator = gocql.PasswordAuthenticator{
+			Username: config.Username,
+			Password: config.Password,
+		}
+	}
+
+	session, err := cluster.CreateSession()
+	if err != nil {
+		return nil, err
+	}
+
+	b.session = session
+	return b.session, nil
+}
+
+const backendHelp = `
+The cassandra backend dynamically generates Cassandra credentials.
+
+After mounting this backend, configure it using the "config/connection"
+path and create roles with the "roles/" path to generate credentials.
+`
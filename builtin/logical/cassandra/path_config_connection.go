@@ -0,0 +1,117 @@
+package cassandra
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/vault/logical"
+	"github.com/hashicorp/vault/logical/framework"
+)
+
+func pathConfigConnection(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "config/connection",
+
+		Fields: map[string]*framework.FieldSchema{
+			"hosts": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Comma-separated list of Cassandra hosts to connect to.",
+			},
+
+			"username": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Username to connect to Cassandra with.",
+			},
+
+			"password": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Password to connect to Cassandra with.",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation:  b.pathConnectionRead,
+			logical.WriteOperation: b.pathConnectionWrite,
+		},
+
+		HelpSynopsis:    "Configure the connection used to communicate with Cassandra.",
+		HelpDescription: "This path configures the hosts and credentials used to connect to Cassandra in order to create and revoke credentials.",
+	}
+}
+
+// cassandraConnectionConfig is the storage representation of config/connection.
+type cassandraConnectionConfig struct {
+	Hosts    string `json:"hosts"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+func (b *backend) pathConnectionRead(req *framework.Request) (*logical.Response, error) {
+	entry, err := req.Storage.Get("config/connection")
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var config cassandraConnectionConfig
+	if err := entry.DecodeJSON(&config); err != nil {
+		return nil, err
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"hosts":    config.Hosts,
+			"username": config.Username,
+		},
+	}, nil
+}
+
+func (b *backend) pathConnectionWrite(req *framework.Request) (*logical.Response, error) {
+	hosts := req.Data.Get("hosts").(string)
+	if hosts == "" {
+		return logical.ErrorResponse("missing hosts"), nil
+	}
+
+	config := &cassandraConnectionConfig{
+		Hosts:    hosts,
+		Username: req.Data.Get("username").(string),
+		Password: req.Data.Get("password").(string),
+	}
+
+	entry, err := logical.StorageEntryJSON("config/connection", config)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(entry); err != nil {
+		return nil, err
+	}
+
+	// The connection parameters changed, so drop any cached session and
+	// reconnect lazily on the next call to DB.
+	b.Lock()
+	defer b.Unlock()
+	if b.session != nil {
+		b.session.Close()
+		b.session = nil
+	}
+
+	return nil, nil
+}
+
+func (b *backend) readConnectionConfig(s logical.Storage) (*cassandraConnectionConfig, error) {
+	entry, err := s.Get("config/connection")
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("cassandra connection not configured; see config/connection")
+	}
+
+	var config cassandraConnectionConfig
+	if err := entry.DecodeJSON(&config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
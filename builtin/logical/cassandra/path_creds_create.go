@@ -0,0 +1,111 @@
+package cassandra
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/vault/logical"
+	"github.com/hashicorp/vault/logical/framework"
+)
+
+func pathCredsCreate(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "creds/" + framework.GenericNameRegex("name"),
+
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Name of the role.",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation: b.pathCredsCreateRead,
+		},
+
+		HelpSynopsis:    "Generate a Cassandra username and password for the given role.",
+		HelpDescription: "This path generates a new set of dynamic Cassandra credentials for the named role, running its creation_cql against the cluster.",
+	}
+}
+
+func (b *backend) pathCredsCreateRead(req *framework.Request) (*logical.Response, error) {
+	name := req.Data.Get("name").(string)
+
+	role, err := getRole(req.Storage, name)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to load role: %s", err)
+	}
+	if role == nil {
+		return logical.ErrorResponse(fmt.Sprintf("Unknown role: %s", name)), nil
+	}
+
+	username, err := generateUsername(name)
+	if err != nil {
+		return nil, err
+	}
+	password, err := uuid.GenerateUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	rollbackStatements := role.RollbackStatements
+	if len(rollbackStatements) == 0 {
+		rollbackStatements = role.RevocationStatements
+	}
+
+	// Record a WAL entry before creating the user so that, if we crash or
+	// error out partway through CreationCQL, the framework's rollback sweep
+	// can still tear down whatever got created.
+	walID, err := framework.PutWAL(req.Storage, "creds", walUser{
+		Username:           username,
+		RollbackStatements: rollbackStatements,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Unable to create WAL entry: %s", err)
+	}
+
+	session, err := b.DB(req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, raw := range splitStatements(role.CreationCQL) {
+		stmt := strings.NewReplacer(
+			"{{username}}", username,
+			"{{password}}", password,
+		).Replace(raw)
+
+		if err := session.Query(stmt).Exec(); err != nil {
+			return nil, fmt.Errorf("Error creating user %s: %s", username, err)
+		}
+	}
+
+	if err := framework.DeleteWAL(req.Storage, walID); err != nil {
+		return nil, fmt.Errorf("Unable to delete WAL entry: %s", err)
+	}
+
+	resp := b.Secret(SecretCredsType).Response(map[string]interface{}{
+		"username": username,
+		"password": password,
+	}, map[string]interface{}{
+		"username": username,
+		"role":     name,
+	})
+	if role.Lease > 0 {
+		resp.Secret.TTL = role.Lease
+	}
+
+	return resp, nil
+}
+
+// generateUsername builds a Cassandra username from the role name plus a
+// random suffix so that concurrent creds/<role> reads don't collide.
+func generateUsername(role string) (string, error) {
+	suffix, err := uuid.GenerateUUID()
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("vault_%s_%s", role, suffix), nil
+}
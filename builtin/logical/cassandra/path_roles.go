@@ -0,0 +1,219 @@
+package cassandra
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/logical"
+	"github.com/hashicorp/vault/logical/framework"
+)
+
+// cassandraRole is the storage representation of a role, including the CQL
+// used to create a user and the CQL used to tear one down.
+type cassandraRole struct {
+	CreationCQL string `json:"creation_cql"`
+
+	// RevocationStatements are executed, in order, against the cluster when
+	// a lease for this role is revoked. Each entry is templated with
+	// {{username}}. Statements that fail because the user no longer exists
+	// are treated as success so that revocation is idempotent.
+	RevocationStatements []string `json:"revocation_statements"`
+
+	// RollbackStatements are executed by the framework's WAL rollback to
+	// clean up a user that was only partially created. When unset,
+	// RevocationStatements is used instead.
+	RollbackStatements []string `json:"rollback_statements"`
+
+	Lease time.Duration `json:"lease"`
+}
+
+func pathListRoles(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "roles/?$",
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ListOperation: b.pathRoleList,
+		},
+
+		HelpSynopsis:    pathRoleHelpSyn,
+		HelpDescription: pathRoleHelpDesc,
+	}
+}
+
+func pathRoles(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "roles/" + framework.GenericNameRegex("name"),
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Name of the role.",
+			},
+
+			"creation_cql": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "CQL statements executed to create and configure the user. Required.",
+			},
+
+			"revocation_statements": &framework.FieldSchema{
+				Type: framework.TypeString,
+				Description: `CQL statements, separated by semicolons, executed in order
+to tear down a user created by this role. Each statement is templated with
+{{username}}. If unset, the built-in default of revoking all permissions and
+dropping the user is used.`,
+			},
+
+			"rollback_statements": &framework.FieldSchema{
+				Type: framework.TypeString,
+				Description: `CQL statements, separated by semicolons, executed in order by
+the framework's WAL rollback to clean up a user that was only partially
+created. Each statement is templated with {{username}}. If unset,
+revocation_statements is used.`,
+			},
+
+			"lease": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Lease time for the role, e.g. '1h'. Defaults to the backend's default lease.",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation:   b.pathRoleRead,
+			logical.WriteOperation:  b.pathRoleCreate,
+			logical.DeleteOperation: b.pathRoleDelete,
+		},
+
+		HelpSynopsis:    pathRoleHelpSyn,
+		HelpDescription: pathRoleHelpDesc,
+	}
+}
+
+func getRole(s logical.Storage, n string) (*cassandraRole, error) {
+	entry, err := s.Get("role/" + n)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var result cassandraRole
+	if err := entry.DecodeJSON(&result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (b *backend) pathRoleDelete(req *framework.Request) (*logical.Response, error) {
+	err := req.Storage.Delete("role/" + req.Data.Get("name").(string))
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (b *backend) pathRoleRead(req *framework.Request) (*logical.Response, error) {
+	role, err := getRole(req.Storage, req.Data.Get("name").(string))
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, nil
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"creation_cql":          role.CreationCQL,
+			"revocation_statements": role.RevocationStatements,
+			"rollback_statements":   role.RollbackStatements,
+			"lease":                 role.Lease.String(),
+		},
+	}, nil
+}
+
+func (b *backend) pathRoleList(req *framework.Request) (*logical.Response, error) {
+	entries, err := req.Storage.List("role/")
+	if err != nil {
+		return nil, err
+	}
+
+	return logical.ListResponse(entries), nil
+}
+
+func (b *backend) pathRoleCreate(req *framework.Request) (*logical.Response, error) {
+	name := req.Data.Get("name").(string)
+	if name == "" {
+		return logical.ErrorResponse("missing name"), nil
+	}
+
+	creationCQL := req.Data.Get("creation_cql").(string)
+	if creationCQL == "" {
+		return logical.ErrorResponse("missing creation_cql"), nil
+	}
+
+	var lease time.Duration
+	leaseRaw := req.Data.Get("lease").(string)
+	if leaseRaw != "" {
+		var err error
+		lease, err = time.ParseDuration(leaseRaw)
+		if err != nil {
+			return logical.ErrorResponse(fmt.Sprintf("invalid lease: %s", err)), nil
+		}
+	}
+
+	role := &cassandraRole{
+		CreationCQL:          creationCQL,
+		RevocationStatements: splitStatements(req.Data.Get("revocation_statements").(string)),
+		RollbackStatements:   splitStatements(req.Data.Get("rollback_statements").(string)),
+		Lease:                lease,
+	}
+
+	entry, err := logical.StorageEntryJSON("role/"+name, role)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(entry); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// splitStatements breaks a semicolon-delimited set of CQL templates into
+// their individual statements, dropping empty entries left by trailing
+// separators.
+func splitStatements(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+
+	parts := strings.Split(raw, ";")
+	result := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		result = append(result, p)
+	}
+
+	return result
+}
+
+const pathRoleHelpSyn = `
+Manage the roles used to generate Cassandra credentials.
+`
+
+const pathRoleHelpDesc = `
+This path lets you manage the roles used to generate Cassandra credentials.
+
+The "creation_cql" parameter customizes the CQL string used to create the
+user, role and grants. The "revocation_statements" and "rollback_statements"
+parameters are semicolon-separated lists of CQL templates, each of which may
+reference the generated username via {{username}}, used to tear down a user
+on lease revocation and to clean up a partially created user found during
+WAL rollback, respectively. When rollback_statements is unset, the
+revocation statements are reused for rollback.
+`
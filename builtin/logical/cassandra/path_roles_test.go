@@ -0,0 +1,105 @@
+package cassandra
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/vault/logical"
+	"github.com/hashicorp/vault/logical/framework"
+)
+
+func TestPathRoles_createAndRead(t *testing.T) {
+	b := Backend()
+	storage := new(logical.InmemStorage)
+
+	req := &framework.Request{
+		Request: &logical.Request{
+			Operation: logical.WriteOperation,
+			Path:      "roles/readonly",
+			Storage:   storage,
+			Data: map[string]interface{}{
+				"name":                  "readonly",
+				"creation_cql":          "CREATE USER '{{username}}' WITH PASSWORD '{{password}}';",
+				"revocation_statements": "REVOKE ALL PERMISSIONS ON ALL KEYSPACES FROM '{{username}}'; DROP USER '{{username}}'",
+				"lease":                 "1h",
+			},
+		},
+	}
+	req.Data = &framework.FieldData{Raw: req.Request.Data, Schema: pathRoles(b).Fields}
+
+	if _, err := b.pathRoleCreate(req); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	role, err := getRole(storage, "readonly")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if role == nil {
+		t.Fatal("expected a role")
+	}
+
+	expectedRevocation := []string{
+		"REVOKE ALL PERMISSIONS ON ALL KEYSPACES FROM '{{username}}'",
+		"DROP USER '{{username}}'",
+	}
+	if !reflect.DeepEqual(role.RevocationStatements, expectedRevocation) {
+		t.Fatalf("bad revocation_statements: %#v", role.RevocationStatements)
+	}
+	if len(role.RollbackStatements) != 0 {
+		t.Fatalf("expected no rollback_statements, got %#v", role.RollbackStatements)
+	}
+	if role.Lease.String() != "1h0m0s" {
+		t.Fatalf("bad lease: %s", role.Lease)
+	}
+}
+
+func TestPathRoles_rollbackFallsBackToRevocation(t *testing.T) {
+	b := Backend()
+	storage := new(logical.InmemStorage)
+
+	req := &framework.Request{
+		Request: &logical.Request{
+			Operation: logical.WriteOperation,
+			Path:      "roles/readonly",
+			Storage:   storage,
+		},
+	}
+	req.Data = &framework.FieldData{
+		Raw: map[string]interface{}{
+			"name":                  "readonly",
+			"creation_cql":          "CREATE USER '{{username}}' WITH PASSWORD '{{password}}';",
+			"revocation_statements": "DROP USER '{{username}}'",
+		},
+		Schema: pathRoles(b).Fields,
+	}
+
+	if _, err := b.pathRoleCreate(req); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	role, err := getRole(storage, "readonly")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	statements := role.RollbackStatements
+	if len(statements) == 0 {
+		statements = role.RevocationStatements
+	}
+	if len(statements) != 1 || statements[0] != "DROP USER '{{username}}'" {
+		t.Fatalf("expected rollback to fall back to revocation_statements, got %#v", statements)
+	}
+}
+
+func TestGetRole_missing(t *testing.T) {
+	storage := new(logical.InmemStorage)
+
+	role, err := getRole(storage, "does-not-exist")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if role != nil {
+		t.Fatalf("expected nil role, got %#v", role)
+	}
+}
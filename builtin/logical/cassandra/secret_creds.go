@@ -2,15 +2,25 @@ package cassandra
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/gocql/gocql"
 	"github.com/hashicorp/vault/logical"
 	"github.com/hashicorp/vault/logical/framework"
+	"github.com/mitchellh/mapstructure"
 )
 
 // SecretCredsType is the type of creds issued from this backend
 const SecretCredsType = "cassandra"
 
+// defaultRevocationStatements is used for roles that don't configure their
+// own revocation_statements.
+var defaultRevocationStatements = []string{
+	`REVOKE ALL PERMISSIONS ON ALL KEYSPACES FROM '{{username}}'`,
+	`DROP USER '{{username}}'`,
+}
+
 func secretCreds(b *backend) *framework.Secret {
 	return &framework.Secret{
 		Type: SecretCredsType,
@@ -26,8 +36,7 @@ func secretCreds(b *backend) *framework.Secret {
 			},
 		},
 
-		DefaultDuration:    1 * time.Hour,
-		DefaultGracePeriod: 10 * time.Minute,
+		DefaultDuration: 1 * time.Hour,
 
 		Renew:  b.secretCredsRenew,
 		Revoke: b.secretCredsRevoke,
@@ -66,20 +75,104 @@ func (b *backend) secretCredsRevoke(
 		return nil, fmt.Errorf("Error converting username internal data to string")
 	}
 
+	statements := defaultRevocationStatements
+	if roleRaw, ok := req.Secret.InternalData["role"]; ok {
+		if roleName, ok := roleRaw.(string); ok {
+			role, err := getRole(req.Storage, roleName)
+			if err != nil {
+				return nil, fmt.Errorf("Unable to load role: %s", err)
+			}
+			if role != nil && len(role.RevocationStatements) > 0 {
+				statements = role.RevocationStatements
+			}
+		}
+	}
+
 	session, err := b.DB(req.Storage)
 	if err != nil {
 		return nil, fmt.Errorf("Error getting session")
 	}
 
-	err = session.Query(fmt.Sprintf("REVOKE ALL PERMISSIONS ON ALL KEYSPACES FROM '%s'", username)).Exec()
+	return nil, runUserStatements(session, statements, username)
+}
+
+// runUserStatements executes each {{username}}-templated CQL statement in
+// order against session, continuing past errors that indicate the user is
+// already gone so that revocation and rollback stay idempotent. Any other
+// failure aborts the loop and is returned.
+func runUserStatements(session *gocql.Session, statements []string, username string) error {
+	for _, raw := range statements {
+		stmt := strings.Replace(raw, "{{username}}", username, -1)
+
+		err := session.Query(stmt).Exec()
+		if err == nil || isNoSuchUserErr(err) {
+			continue
+		}
+
+		return fmt.Errorf("Error running %q: %s", stmt, err)
+	}
+
+	return nil
+}
+
+// isNoSuchUserErr reports whether err looks like Cassandra's response to
+// operating on a role/user that has already been removed, which revocation
+// and rollback should tolerate rather than fail on.
+func isNoSuchUserErr(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "doesn't exist") || strings.Contains(msg, "does not exist")
+}
+
+// secretCredsRollback is registered as the backend's WAL Rollback callback.
+// It tears down a user that was only partially created (e.g. the CREATE
+// succeeded but a later GRANT in creation_cql failed). The framework's
+// Rollback signature doesn't carry request storage, so the statements to
+// run are resolved once, from the role, at creds-creation time and baked
+// into the WAL entry itself rather than looked up here.
+func (b *backend) secretCredsRollback(kind string, data interface{}) bool {
+	entry, err := decodeWALUser(data)
 	if err != nil {
-		return nil, fmt.Errorf("Error revoking permissions for user %s", username)
+		return false
+	}
+
+	statements := entry.RollbackStatements
+	if len(statements) == 0 {
+		statements = defaultRevocationStatements
 	}
 
-	err = session.Query(fmt.Sprintf("DROP USER '%s'", username)).Exec()
+	// No request storage is available in this callback; b.DB relies on the
+	// connection already being cached from when the user was created.
+	session, err := b.DB(nil)
 	if err != nil {
-		return nil, fmt.Errorf("Error removing user %s", username)
+		return false
+	}
+
+	return runUserStatements(session, statements, entry.Username) == nil
+}
+
+// walUser is the WAL entry written while a user is being created, so that a
+// crash partway through creation can be rolled back. RollbackStatements is
+// the role's rollback_statements (or its revocation_statements, if that's
+// all it configured), resolved once at creation time.
+type walUser struct {
+	Username           string
+	RollbackStatements []string
+}
+
+// decodeWALUser decodes a WALEntry.Data back into a walUser. By the time
+// Backend.handleRollback calls Backend.Rollback, data has round-tripped
+// through JSON via PutWAL/GetWAL and arrived as a map[string]interface{}
+// rather than the walUser it was written as, so it has to be decoded
+// rather than type-asserted.
+func decodeWALUser(data interface{}) (*walUser, error) {
+	var entry walUser
+	if err := mapstructure.Decode(data, &entry); err != nil {
+		return nil, err
 	}
 
-	return nil, nil
+	return &entry, nil
 }
@@ -0,0 +1,118 @@
+package cassandra
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/vault/logical"
+	"github.com/hashicorp/vault/logical/framework"
+)
+
+func TestSplitStatements(t *testing.T) {
+	cases := map[string]struct {
+		Raw      string
+		Expected []string
+	}{
+		"empty": {
+			"",
+			nil,
+		},
+
+		"single statement": {
+			"DROP USER '{{username}}'",
+			[]string{"DROP USER '{{username}}'"},
+		},
+
+		"multiple statements": {
+			"REVOKE ALL PERMISSIONS ON ALL KEYSPACES FROM '{{username}}'; DROP USER '{{username}}'",
+			[]string{
+				"REVOKE ALL PERMISSIONS ON ALL KEYSPACES FROM '{{username}}'",
+				"DROP USER '{{username}}'",
+			},
+		},
+
+		"trailing separator": {
+			"DROP USER '{{username}}';",
+			[]string{"DROP USER '{{username}}'"},
+		},
+
+		"blank statements between separators are dropped": {
+			"DROP USER '{{username}}';;  ;ALTER ROLE '{{username}}' NOLOGIN",
+			[]string{
+				"DROP USER '{{username}}'",
+				"ALTER ROLE '{{username}}' NOLOGIN",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		actual := splitStatements(tc.Raw)
+		if !reflect.DeepEqual(actual, tc.Expected) {
+			t.Fatalf("%s: expected %#v, got %#v", name, tc.Expected, actual)
+		}
+	}
+}
+
+func TestIsNoSuchUserErr(t *testing.T) {
+	cases := map[string]struct {
+		Err      error
+		Expected bool
+	}{
+		"nil error":              {nil, false},
+		"unrelated error":        {fmt.Errorf("connection reset by peer"), false},
+		"does not exist":         {fmt.Errorf("line 1:0 user jdoe does not exist"), true},
+		"doesn't exist, mixed case": {fmt.Errorf("Role 'jdoe' Doesn't Exist"), true},
+	}
+
+	for name, tc := range cases {
+		if actual := isNoSuchUserErr(tc.Err); actual != tc.Expected {
+			t.Fatalf("%s: expected %v, got %v", name, tc.Expected, actual)
+		}
+	}
+}
+
+// TestSecretCredsRollback_decodesWALRoundTrip guards against data.(walUser)
+// style type assertions on WAL data: once a walUser has been through
+// PutWAL/GetWAL it comes back as a map[string]interface{}, not the
+// original struct, so the rollback path must decode it rather than assert
+// its type.
+func TestSecretCredsRollback_decodesWALRoundTrip(t *testing.T) {
+	storage := new(logical.InmemStorage)
+
+	written := walUser{
+		Username:           "vault-test-1234",
+		RollbackStatements: []string{"DROP USER '{{username}}'"},
+	}
+
+	id, err := framework.PutWAL(storage, "creds", written)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	entry, err := framework.GetWAL(storage, id)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if entry == nil {
+		t.Fatal("expected a WAL entry")
+	}
+
+	// Sanity check that the round trip actually decayed the concrete type,
+	// i.e. that this test would have caught the bug it's guarding against.
+	if _, ok := entry.Data.(walUser); ok {
+		t.Fatal("expected entry.Data to no longer be a walUser after the WAL round trip")
+	}
+
+	decoded, err := decodeWALUser(entry.Data)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if decoded.Username != written.Username {
+		t.Fatalf("bad username: %#v", decoded.Username)
+	}
+	if !reflect.DeepEqual(decoded.RollbackStatements, written.RollbackStatements) {
+		t.Fatalf("bad rollback statements: %#v", decoded.RollbackStatements)
+	}
+}
@@ -0,0 +1,258 @@
+package framework
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/vault/logical"
+)
+
+// RollbackFunc is the callback invoked for each WAL entry during a
+// RollbackOperation. It returns true if the entry was successfully rolled
+// back and can be removed from the WAL. Returning false means "retry
+// later": the entry is kept, its attempt count is bumped, and it won't be
+// tried again until its backoff elapses.
+type RollbackFunc func(kind string, data interface{}) bool
+
+const (
+	// DefaultRollbackBatchSize caps how many WAL entries a single
+	// RollbackOperation sweep processes when Backend.RollbackBatchSize is
+	// unset, so one sweep can't be monopolized by a single mount with a
+	// huge backlog.
+	DefaultRollbackBatchSize = 100
+
+	// DefaultRollbackMaxBackoff is the backoff ceiling used when
+	// Backend.RollbackMaxBackoff is unset.
+	DefaultRollbackMaxBackoff = 24 * time.Hour
+
+	rollbackBaseBackoff = time.Second
+)
+
+// Backend is an implementation of logical.Backend that allows the common
+// patterns of a logical backend to be implemented in a much higher-level
+// way than implementing logical.Backend directly.
+type Backend struct {
+	Help    string
+	Paths   []*Path
+	Secrets []*Secret
+
+	// Rollback is called for every due WAL entry older than RollbackMinAge
+	// on a RollbackOperation request.
+	Rollback       RollbackFunc
+	RollbackMinAge time.Duration
+
+	// RollbackBatchSize bounds how many WAL entries a single
+	// RollbackOperation sweep processes. Defaults to
+	// DefaultRollbackBatchSize.
+	RollbackBatchSize int
+
+	// RollbackMaxBackoff caps the exponential backoff applied to an entry
+	// that repeatedly fails to roll back. Defaults to
+	// DefaultRollbackMaxBackoff.
+	RollbackMaxBackoff time.Duration
+
+	once   sync.Once
+	router *pathRouter
+}
+
+func (b *Backend) init() {
+	paths := make([]*Path, 0, len(b.Paths)+2)
+	paths = append(paths, b.Paths...)
+	paths = append(paths, walPaths()...)
+	b.router = newPathRouter(paths)
+}
+
+// Secret looks up the *Secret registered under the given type name, or nil
+// if none was registered.
+func (b *Backend) Secret(name string) *Secret {
+	for _, s := range b.Secrets {
+		if s.Type == name {
+			return s
+		}
+	}
+
+	return nil
+}
+
+// Route looks up the path that would be used for a given request path,
+// without actually routing the request. The returned Path may be nil if no
+// match was found.
+func (b *Backend) Route(path string) *Path {
+	result, _ := b.match(path)
+	return result
+}
+
+// match is Route plus the named captures from the winning Pattern, so that
+// HandleRequest doesn't need to re-run the pattern's regexp a second time
+// just to pull the fields back out.
+func (b *Backend) match(path string) (*Path, map[string]string) {
+	b.once.Do(b.init)
+	return b.router.Match(path)
+}
+
+// HandleRequest is the logical.Backend implementation.
+func (b *Backend) HandleRequest(req *logical.Request) (*logical.Response, error) {
+	if req.Operation == logical.RollbackOperation {
+		return b.handleRollback(req)
+	}
+
+	path, captures := b.match(req.Path)
+	if path == nil {
+		return nil, logical.ErrUnsupportedPath
+	}
+
+	fd := &FieldData{
+		Raw:    mergeCaptures(req.Data, captures),
+		Schema: path.Fields,
+	}
+	if req.Operation != logical.HelpOperation {
+		if err := fd.Validate(); err != nil {
+			return nil, fmt.Errorf("error validating request: %s", err)
+		}
+	}
+
+	switch req.Operation {
+	case logical.HelpOperation:
+		return path.helpCallback(&Request{Request: req, Data: fd}, fd)
+	}
+
+	callback, ok := path.Callbacks[req.Operation]
+	if !ok {
+		return nil, logical.ErrUnsupportedOperation
+	}
+
+	return callback(&Request{Request: req, Data: fd})
+}
+
+// mergeCaptures layers the named captures from the matched Pattern on top
+// of the request's own data, so a value captured from the URL always wins
+// over one of the same name supplied in the request body.
+func mergeCaptures(data map[string]interface{}, captures map[string]string) map[string]interface{} {
+	if len(captures) == 0 {
+		return data
+	}
+
+	raw := make(map[string]interface{}, len(data)+len(captures))
+	for k, v := range data {
+		raw[k] = v
+	}
+	for k, v := range captures {
+		raw[k] = v
+	}
+
+	return raw
+}
+
+// handleRollback processes up to RollbackBatchSize due WAL entries,
+// isolating failures per entry: a failing entry gets its attempt count and
+// backoff bumped and is left for a later sweep rather than aborting the
+// rest of the batch or being dropped. All per-entry failures are
+// aggregated into a single returned error.
+func (b *Backend) handleRollback(req *logical.Request) (*logical.Response, error) {
+	batchSize := b.RollbackBatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultRollbackBatchSize
+	}
+
+	ids, err := ListWAL(req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *multierror.Error
+	now := time.Now()
+	processed := 0
+
+	for _, id := range ids {
+		if processed >= batchSize {
+			break
+		}
+
+		entry, err := GetWAL(req.Storage, id)
+		if err != nil {
+			result = multierror.Append(result, fmt.Errorf("error reading WAL entry %q: %s", id, err))
+			continue
+		}
+		if entry == nil {
+			continue
+		}
+
+		if now.Sub(entry.CreatedTime) < b.RollbackMinAge {
+			continue
+		}
+		if !entry.NextRetryTime.IsZero() && now.Before(entry.NextRetryTime) {
+			continue
+		}
+
+		processed++
+
+		if b.Rollback != nil && b.Rollback(entry.Kind, entry.Data) {
+			if err := DeleteWAL(req.Storage, id); err != nil {
+				result = multierror.Append(result, fmt.Errorf("error deleting WAL entry %q: %s", id, err))
+			}
+			continue
+		}
+
+		entry.Attempts++
+		entry.NextRetryTime = now.Add(b.rollbackBackoff(entry.Attempts))
+		if err := putWALEntry(req.Storage, entry); err != nil {
+			result = multierror.Append(result, fmt.Errorf("error updating WAL entry %q: %s", id, err))
+			continue
+		}
+
+		result = multierror.Append(result, fmt.Errorf(
+			"WAL entry %q of kind %q not yet rolled back (attempt %d)", id, entry.Kind, entry.Attempts))
+	}
+
+	return nil, result.ErrorOrNil()
+}
+
+// rollbackBackoff computes an exponentially increasing backoff for the
+// given attempt count, capped at RollbackMaxBackoff (or
+// DefaultRollbackMaxBackoff) and jittered by up to 50% so that a cluster of
+// entries that failed at the same time don't all retry in lockstep.
+func (b *Backend) rollbackBackoff(attempts int) time.Duration {
+	max := b.RollbackMaxBackoff
+	if max <= 0 {
+		max = DefaultRollbackMaxBackoff
+	}
+
+	backoff := rollbackBaseBackoff
+	for i := 0; i < attempts && backoff < max; i++ {
+		backoff *= 2
+	}
+	if backoff > max {
+		backoff = max
+	}
+
+	jitter := time.Duration(rand.Int63n(int64(backoff)/2 + 1))
+	return backoff/2 + jitter
+}
+
+// WAL returns the WAL entries currently stored for this backend. It's the
+// programmatic equivalent of the wal/ path that Backend.init wires into
+// every backend's router (see path_wal.go), which is what gives operators a
+// sys/wal style read-only listing of entries that are stuck retrying
+// without reaching into storage directly.
+func (b *Backend) WAL(s logical.Storage) ([]*WALEntry, error) {
+	ids, err := ListWAL(s)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*WALEntry, 0, len(ids))
+	for _, id := range ids {
+		entry, err := GetWAL(s, id)
+		if err != nil {
+			return nil, fmt.Errorf("error reading WAL entry %q: %s", id, err)
+		}
+		if entry != nil {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
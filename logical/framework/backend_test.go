@@ -1,6 +1,7 @@
 package framework
 
 import (
+	"fmt"
 	"reflect"
 	"sync/atomic"
 	"testing"
@@ -14,8 +15,8 @@ func BenchmarkBackendRoute(b *testing.B) {
 		"foo",
 		"bar/(?P<name>.+?)",
 		"baz/(?P<name>what)",
-		`aws/policy/(?P<policy>\w)`,
-		`aws/(?P<policy>\w)`,
+		`aws/policy/(?P<policy>\w+)`,
+		`aws/(?P<policy>\w+)`,
 	}
 
 	backend := &Backend{Paths: make([]*Path, 0, len(patterns))}
@@ -37,6 +38,82 @@ func BenchmarkBackendRoute(b *testing.B) {
 	}
 }
 
+// benchmarkBackendRouteScale measures Route against a mount with n distinct
+// patterns, most sharing a common literal prefix the way a real mount's
+// "roles/<name>" style paths do, so the trie actually has breadth to prune.
+func benchmarkBackendRouteScale(b *testing.B, n int) {
+	patterns := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		patterns = append(patterns, fmt.Sprintf(`roles/role-%d/(?P<field>\w+)`, i))
+	}
+
+	backend := &Backend{Paths: make([]*Path, 0, n)}
+	for _, p := range patterns {
+		backend.Paths = append(backend.Paths, &Path{Pattern: p})
+	}
+
+	path := fmt.Sprintf("roles/role-%d/foo", n-1)
+
+	// Warm any caches
+	backend.Route(path)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if p := backend.Route(path); p == nil {
+			b.Fatal("p should not be nil")
+		}
+	}
+}
+
+func BenchmarkBackendRoute50(b *testing.B)   { benchmarkBackendRouteScale(b, 50) }
+func BenchmarkBackendRoute500(b *testing.B)  { benchmarkBackendRouteScale(b, 500) }
+func BenchmarkBackendRoute5000(b *testing.B) { benchmarkBackendRouteScale(b, 5000) }
+
+func TestBackendRoute_priority(t *testing.T) {
+	cases := map[string]struct {
+		Patterns []string
+		Path     string
+		Match    string
+	}{
+		"literal beats regex at the same depth": {
+			[]string{`foo/(?P<name>.+)`, "foo/bar"},
+			"foo/bar",
+			"foo/bar",
+		},
+
+		"longer literal beats shorter": {
+			[]string{`foo/(?P<name>.+)`, `foo/bar/(?P<name>.+)`, "foo/bar/baz"},
+			"foo/bar/baz",
+			"foo/bar/baz",
+		},
+
+		"regex still matches when no literal does": {
+			[]string{"foo/bar", `foo/(?P<name>.+)`},
+			"foo/qux",
+			`foo/(?P<name>.+)`,
+		},
+	}
+
+	for n, tc := range cases {
+		paths := make([]*Path, len(tc.Patterns))
+		for i, pattern := range tc.Patterns {
+			paths[i] = &Path{Pattern: pattern}
+		}
+
+		b := &Backend{Paths: paths}
+		result := b.Route(tc.Path)
+		match := ""
+		if result != nil {
+			match = result.Pattern
+		}
+
+		if match != tc.Match {
+			t.Fatalf("bad: %s\n\nExpected: %s\nGot: %s", n, tc.Match, match)
+		}
+	}
+}
+
 func TestBackend_impl(t *testing.T) {
 	var _ logical.Backend = new(Backend)
 }
@@ -205,6 +282,153 @@ func TestBackendHandleRequest_rollbackMinAge(t *testing.T) {
 	}
 }
 
+func TestBackendHandleRequest_rollbackContinuesPastFailure(t *testing.T) {
+	var goodCalls, badCalls uint32
+	callback := func(kind string, data interface{}) bool {
+		if kind == "bad" {
+			atomic.AddUint32(&badCalls, 1)
+			return false
+		}
+
+		atomic.AddUint32(&goodCalls, 1)
+		return true
+	}
+
+	b := &Backend{
+		Rollback:       callback,
+		RollbackMinAge: 1 * time.Millisecond,
+	}
+
+	storage := new(logical.InmemStorage)
+	badID, err := PutWAL(storage, "bad", "foo")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, err := PutWAL(storage, "good", "bar"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+
+	_, err = b.HandleRequest(&logical.Request{
+		Operation: logical.RollbackOperation,
+		Path:      "",
+		Storage:   storage,
+	})
+	if err == nil {
+		t.Fatalf("expected an aggregated error for the still-failing entry")
+	}
+	if v := atomic.LoadUint32(&goodCalls); v != 1 {
+		t.Fatalf("bad: good calls = %#v", v)
+	}
+	if v := atomic.LoadUint32(&badCalls); v != 1 {
+		t.Fatalf("bad: bad calls = %#v", v)
+	}
+
+	entry, err := GetWAL(storage, badID)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if entry == nil {
+		t.Fatal("failed entry should not have been dropped from the WAL")
+	}
+	if entry.Attempts != 1 {
+		t.Fatalf("bad: attempts = %d", entry.Attempts)
+	}
+	if !entry.NextRetryTime.After(time.Now()) {
+		t.Fatalf("bad: next retry time should be in the future: %s", entry.NextRetryTime)
+	}
+}
+
+func TestBackendHandleRequest_rollbackBatchSize(t *testing.T) {
+	var called uint32
+	callback := func(kind string, data interface{}) bool {
+		atomic.AddUint32(&called, 1)
+		return true
+	}
+
+	b := &Backend{
+		Rollback:          callback,
+		RollbackMinAge:    1 * time.Millisecond,
+		RollbackBatchSize: 2,
+	}
+
+	storage := new(logical.InmemStorage)
+	for i := 0; i < 5; i++ {
+		if _, err := PutWAL(storage, "kind", "foo"); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	time.Sleep(10 * time.Millisecond)
+
+	_, err := b.HandleRequest(&logical.Request{
+		Operation: logical.RollbackOperation,
+		Path:      "",
+		Storage:   storage,
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if v := atomic.LoadUint32(&called); v != 2 {
+		t.Fatalf("bad: expected only RollbackBatchSize entries to be processed, got %#v", v)
+	}
+}
+
+func TestBackendWAL(t *testing.T) {
+	storage := new(logical.InmemStorage)
+	if _, err := PutWAL(storage, "kind", "foo"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	b := &Backend{}
+	entries, err := b.WAL(storage)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("bad: %#v", entries)
+	}
+	if entries[0].Kind != "kind" {
+		t.Fatalf("bad: %#v", entries[0])
+	}
+}
+
+func TestBackendHandleRequest_walPaths(t *testing.T) {
+	storage := new(logical.InmemStorage)
+	id, err := PutWAL(storage, "kind", "foo")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	b := &Backend{}
+
+	listResp, err := b.HandleRequest(&logical.Request{
+		Operation: logical.ListOperation,
+		Path:      "wal/",
+		Storage:   storage,
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	keys, ok := listResp.Data["keys"].([]string)
+	if !ok || len(keys) != 1 || keys[0] != id {
+		t.Fatalf("bad: %#v", listResp.Data)
+	}
+
+	readResp, err := b.HandleRequest(&logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "wal/" + id,
+		Storage:   storage,
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if readResp.Data["kind"] != "kind" {
+		t.Fatalf("bad: %#v", readResp.Data)
+	}
+}
+
 func TestBackendHandleRequest_unsupportedOperation(t *testing.T) {
 	callback := func(req *Request) (*logical.Response, error) {
 		return &logical.Response{
@@ -0,0 +1,177 @@
+package framework
+
+import (
+	"fmt"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// FieldType is the enum of types that a field can be.
+type FieldType uint
+
+const (
+	TypeInvalid FieldType = 0
+	TypeString  FieldType = iota
+	TypeInt
+	TypeBool
+	TypeMap
+	TypeDurationSecond
+)
+
+// FieldSchema is a basic schema to describe the format of a path field.
+type FieldSchema struct {
+	Type        FieldType
+	Default     interface{}
+	Description string
+}
+
+// DefaultOrZero returns the default value if it is set, or otherwise
+// the zero value of the type.
+func (s *FieldSchema) DefaultOrZero() interface{} {
+	if s.Default != nil {
+		return s.Default
+	}
+
+	switch s.Type {
+	case TypeString:
+		return ""
+	case TypeInt:
+		return 0
+	case TypeBool:
+		return false
+	case TypeMap:
+		return map[string]interface{}{}
+	case TypeDurationSecond:
+		return 0
+	default:
+		panic("unknown type: " + fmt.Sprintf("%v", s.Type))
+	}
+}
+
+// FieldData is the structured data that is passed to a callback for a path,
+// built from the raw request data plus the captures from the matched
+// pattern, validated and coerced against a set of FieldSchemas.
+type FieldData struct {
+	Raw    map[string]interface{}
+	Schema map[string]*FieldSchema
+}
+
+// Validate ensures that each field of the raw data that appears in the
+// schema can be successfully decoded into its schema type. Fields that are
+// not in the schema are skipped, not rejected.
+func (d *FieldData) Validate() error {
+	for field, value := range d.Raw {
+		schema, ok := d.Schema[field]
+		if !ok {
+			continue
+		}
+
+		switch schema.Type {
+		case TypeString, TypeInt, TypeBool, TypeMap, TypeDurationSecond:
+			if _, _, err := d.getPrimitive(field, value, schema); err != nil {
+				return fmt.Errorf("error converting input %v for field %q: %s", value, field, err)
+			}
+		default:
+			return fmt.Errorf("unknown field type %q for field %q", schema.Type, field)
+		}
+	}
+
+	return nil
+}
+
+// Get gets the value for the given field. If the key is an invalid field,
+// FieldData will panic. If you want a safer version of this method, use
+// GetOk. If the field is valid but not set, the default value (or the
+// zero value) is returned.
+func (d *FieldData) Get(k string) interface{} {
+	schema, ok := d.Schema[k]
+	if !ok {
+		panic(fmt.Sprintf("field %q not in the schema", k))
+	}
+
+	value, ok, err := d.GetOkErr(k)
+	if err != nil {
+		panic(fmt.Sprintf("error reading %s: %s", k, err))
+	}
+	if !ok {
+		value = schema.DefaultOrZero()
+	}
+
+	return value
+}
+
+// GetOk gets the value for the given field, returning whether it was
+// present in the raw data.
+func (d *FieldData) GetOk(k string) (interface{}, bool) {
+	result, ok, err := d.GetOkErr(k)
+	if err != nil {
+		panic(fmt.Sprintf("error reading %s: %s", k, err))
+	}
+
+	return result, ok
+}
+
+// GetOkErr is the most underlying primitive of the Get* methods: it returns
+// whether the key was present and any error converting it to the schema
+// type.
+func (d *FieldData) GetOkErr(k string) (interface{}, bool, error) {
+	schema, ok := d.Schema[k]
+	if !ok {
+		return nil, false, fmt.Errorf("unknown field: %q", k)
+	}
+
+	raw, ok := d.Raw[k]
+	if !ok {
+		return nil, false, nil
+	}
+
+	switch schema.Type {
+	case TypeString, TypeInt, TypeBool, TypeMap, TypeDurationSecond:
+		return d.getPrimitive(k, raw, schema)
+	default:
+		return nil, false, fmt.Errorf("unknown field type %q for field %q", schema.Type, k)
+	}
+}
+
+func (d *FieldData) getPrimitive(
+	k string, raw interface{}, schema *FieldSchema) (interface{}, bool, error) {
+	switch schema.Type {
+	case TypeString:
+		var result string
+		if err := mapstructure.WeakDecode(raw, &result); err != nil {
+			return nil, false, err
+		}
+		return result, true, nil
+
+	case TypeInt:
+		var result int
+		if err := mapstructure.WeakDecode(raw, &result); err != nil {
+			return nil, false, err
+		}
+		return result, true, nil
+
+	case TypeBool:
+		var result bool
+		if err := mapstructure.WeakDecode(raw, &result); err != nil {
+			return nil, false, err
+		}
+		return result, true, nil
+
+	case TypeMap:
+		var result map[string]interface{}
+		if err := mapstructure.WeakDecode(raw, &result); err != nil {
+			return nil, false, err
+		}
+		return result, true, nil
+
+	case TypeDurationSecond:
+		var result int
+		if err := mapstructure.WeakDecode(raw, &result); err != nil {
+			return nil, false, err
+		}
+		return result, true, nil
+
+	default:
+		return nil, false, fmt.Errorf("unknown type: %v", schema.Type)
+	}
+}
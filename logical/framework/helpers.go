@@ -0,0 +1,11 @@
+package framework
+
+import "fmt"
+
+// GenericNameRegex returns a regex pattern fragment that captures a generic
+// name (letters, numbers, dashes, underscores, dots) into the named group
+// name. It's meant to be embedded in a Path's Pattern, e.g.
+// "roles/" + GenericNameRegex("name").
+func GenericNameRegex(name string) string {
+	return fmt.Sprintf(`(?P<%s>\w(([\w-.]+)?\w)?)`, name)
+}
@@ -0,0 +1,51 @@
+package framework
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/vault/logical"
+)
+
+// OperationFunc is the callback called for an operation on a path.
+type OperationFunc func(req *Request) (*logical.Response, error)
+
+// ExistenceFunc is the callback called for an existence check on a path.
+type ExistenceFunc func(req *Request, data *FieldData) (bool, error)
+
+// Path is a single path that the backend responds to.
+type Path struct {
+	// Pattern is the pattern of the URL that matches this path.
+	//
+	// This should be a valid regular expression. Named captures will be
+	// exposed as fields that should map to a schema in Fields. If a named
+	// capture is not a field in the Fields map, it is still merged into
+	// FieldData.Raw, but FieldData.Get and GetOk panic when asked for it.
+	Pattern string
+
+	// Fields is the mapping of data fields to a schema describing that
+	// field. Named captures in the Pattern also count as a data field.
+	Fields map[string]*FieldSchema
+
+	// Callbacks are the set of callbacks that are called for a given
+	// operation. If a callback for a specific operation is not present,
+	// then logical.ErrUnsupportedOperation is returned.
+	Callbacks map[logical.Operation]OperationFunc
+
+	// ExistenceCheck, if implemented, is used to query whether a given
+	// resource exists or not. This is used for ACL purposes: if a path
+	// doesn't have an existence check, it is assumed to require a
+	// create-capable ACL for write access to it.
+	ExistenceCheck ExistenceFunc
+
+	// HelpSynopsis and HelpDescription are used to provide help for this
+	// path. HelpSynopsis can be a one-sentence description and
+	// HelpDescription can be a full-fledged description.
+	HelpSynopsis    string
+	HelpDescription string
+}
+
+func (p *Path) helpCallback(req *Request, data *FieldData) (*logical.Response, error) {
+	return logical.HelpResponse(fmt.Sprintf(
+		"## DESCRIPTION\n\n%s\n\n%s",
+		p.HelpSynopsis, p.HelpDescription), nil), nil
+}
@@ -0,0 +1,141 @@
+package framework
+
+import (
+	"regexp"
+	"strings"
+)
+
+// routeEntry is a single compiled Path, ready to be matched against a
+// request path.
+type routeEntry struct {
+	path *Path
+	re   *regexp.Regexp
+}
+
+// pathRouterNode is one level of the router's trie. Each node represents a
+// literal path segment that every entry reachable below it shares.
+type pathRouterNode struct {
+	// children holds the next literal segment of a pattern, e.g. the
+	// pattern "aws/policy/(?P<policy>\w)" contributes to children["aws"].
+	children map[string]*pathRouterNode
+
+	// leaves holds entries whose pattern ends exactly at this node (no
+	// further segments), in insertion order.
+	leaves []*routeEntry
+
+	// regexLeaves holds entries whose pattern has a capture or
+	// metacharacter starting at this depth, in insertion order. These are
+	// only evaluated with the full regexp once the literal children have
+	// failed to produce a match, so a literal match always wins over a
+	// regex one at the same depth, and a deeper literal match wins over a
+	// shallower regex one.
+	regexLeaves []*routeEntry
+}
+
+// pathRouter is a compiled, read-only view of a Backend's Paths that
+// answers Match(path) without re-running every pattern's regexp on every
+// request, the way the original linear scan over Backend.Paths did.
+type pathRouter struct {
+	root *pathRouterNode
+}
+
+// metaChars are the regexp special characters that can appear in a Pattern.
+// A path segment containing none of these is a plain literal and can be
+// routed with a map lookup instead of a regexp evaluation.
+const metaChars = `\.+*?()|[]{}^$`
+
+func isLiteralSegment(seg string) bool {
+	return !strings.ContainsAny(seg, metaChars)
+}
+
+func newPathRouterNode() *pathRouterNode {
+	return &pathRouterNode{children: make(map[string]*pathRouterNode)}
+}
+
+// newPathRouter compiles paths into a trie, preserving their relative order
+// as the tie-breaker between two patterns that match at the same depth.
+func newPathRouter(paths []*Path) *pathRouter {
+	router := &pathRouter{root: newPathRouterNode()}
+	for _, p := range paths {
+		router.add(p)
+	}
+
+	return router
+}
+
+func (r *pathRouter) add(p *Path) {
+	entry := &routeEntry{
+		path: p,
+		re:   regexp.MustCompile("^" + p.Pattern + "$"),
+	}
+
+	segments := strings.Split(p.Pattern, "/")
+	node := r.root
+	for i, seg := range segments {
+		if !isLiteralSegment(seg) {
+			node.regexLeaves = append(node.regexLeaves, entry)
+			return
+		}
+
+		child, ok := node.children[seg]
+		if !ok {
+			child = newPathRouterNode()
+			node.children[seg] = child
+		}
+		node = child
+
+		if i == len(segments)-1 {
+			node.leaves = append(node.leaves, entry)
+		}
+	}
+}
+
+// Match finds the highest-priority Path matching path, along with the
+// named captures from its Pattern. It returns (nil, nil) if nothing
+// matches.
+func (r *pathRouter) Match(path string) (*Path, map[string]string) {
+	segments := strings.Split(path, "/")
+	entry := r.root.match(segments, path)
+	if entry == nil {
+		return nil, nil
+	}
+
+	return entry.path, captures(entry.re, path)
+}
+
+func (n *pathRouterNode) match(segments []string, path string) *routeEntry {
+	if len(segments) > 0 {
+		if child, ok := n.children[segments[0]]; ok {
+			if found := child.match(segments[1:], path); found != nil {
+				return found
+			}
+		}
+	} else if len(n.leaves) > 0 {
+		return n.leaves[0]
+	}
+
+	for _, entry := range n.regexLeaves {
+		if entry.re.MatchString(path) {
+			return entry
+		}
+	}
+
+	return nil
+}
+
+// captures extracts the named capture groups from re's match against path.
+func captures(re *regexp.Regexp, path string) map[string]string {
+	matches := re.FindStringSubmatch(path)
+	if matches == nil {
+		return nil
+	}
+
+	result := make(map[string]string)
+	for i, name := range re.SubexpNames() {
+		if name != "" && i < len(matches) {
+			result[name] = matches[i]
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,82 @@
+package framework
+
+import (
+	"github.com/hashicorp/vault/logical"
+)
+
+// walPaths returns the wal/ paths that Backend.init wires into every
+// Backend's router, giving operators a sys/wal style read-only listing of
+// WAL entries: wal/ lists pending entry IDs, and wal/<id> reads a single
+// entry, including its retry state, without reaching into storage directly.
+func walPaths() []*Path {
+	return []*Path{
+		{
+			Pattern: "wal/?$",
+
+			Callbacks: map[logical.Operation]OperationFunc{
+				logical.ListOperation: pathWALList,
+			},
+
+			HelpSynopsis:    pathWALHelpSyn,
+			HelpDescription: pathWALHelpDesc,
+		},
+		{
+			Pattern: "wal/" + GenericNameRegex("id"),
+			Fields: map[string]*FieldSchema{
+				"id": &FieldSchema{
+					Type:        TypeString,
+					Description: "ID of the WAL entry.",
+				},
+			},
+
+			Callbacks: map[logical.Operation]OperationFunc{
+				logical.ReadOperation: pathWALRead,
+			},
+
+			HelpSynopsis:    pathWALHelpSyn,
+			HelpDescription: pathWALHelpDesc,
+		},
+	}
+}
+
+func pathWALList(req *Request) (*logical.Response, error) {
+	ids, err := ListWAL(req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	return logical.ListResponse(ids), nil
+}
+
+func pathWALRead(req *Request) (*logical.Response, error) {
+	entry, err := GetWAL(req.Storage, req.Data.Get("id").(string))
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"id":              entry.ID,
+			"kind":            entry.Kind,
+			"data":            entry.Data,
+			"created_time":    entry.CreatedTime,
+			"attempts":        entry.Attempts,
+			"next_retry_time": entry.NextRetryTime,
+		},
+	}, nil
+}
+
+const pathWALHelpSyn = `
+Inspect write-ahead-log entries pending rollback.
+`
+
+const pathWALHelpDesc = `
+This path is a read-only, sys/wal style listing of the WAL entries this
+backend has written for RollbackOperation to process. It's useful for
+diagnosing an entry that keeps backing off instead of rolling back:
+wal/ lists pending entry IDs, and wal/<id> reads a single entry's kind,
+data, and retry state.
+`
@@ -0,0 +1,14 @@
+package framework
+
+import (
+	"github.com/hashicorp/vault/logical"
+)
+
+// Request is a high-level structure passed to a Path's OperationFunc,
+// pairing the raw logical.Request with the FieldData built from the
+// request's data and the fields captured from the matched Pattern.
+type Request struct {
+	*logical.Request
+
+	Data *FieldData
+}
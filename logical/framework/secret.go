@@ -0,0 +1,63 @@
+package framework
+
+import (
+	"time"
+
+	"github.com/hashicorp/vault/logical"
+)
+
+// Secret is a type of secret that can be returned from a backend.
+type Secret struct {
+	// Type is the name of this secret type. This is used to setup the
+	// mapping between a secret and this structure.
+	Type string
+
+	// Fields is the mapping of data fields and schema that comprise the
+	// structure of this secret.
+	Fields map[string]*FieldSchema
+
+	// DefaultDuration is the default lease duration if the backend doesn't
+	// explicitly override it on the returned secret.
+	DefaultDuration time.Duration
+
+	// Renew and Revoke are the callbacks to call for these operations. If
+	// these don't exist, then they aren't supported. Unlike Path callbacks,
+	// these are called directly with the raw request and field data rather
+	// than through the Request wrapper, since secrets are invoked outside
+	// of the normal routed request flow.
+	Renew  func(*logical.Request, *FieldData) (*logical.Response, error)
+	Revoke func(*logical.Request, *FieldData) (*logical.Response, error)
+}
+
+// Response builds a *logical.Response for this secret type, setting the
+// default lease duration declared on the Secret and stashing internalData on
+// the resulting logical.SecretData so Renew/Revoke can read it back out of
+// req.Secret.InternalData.
+func (s *Secret) Response(data, internalData map[string]interface{}) *logical.Response {
+	return &logical.Response{
+		Data: data,
+		Secret: &logical.SecretData{
+			InternalData: internalData,
+			LeaseOptions: logical.LeaseOptions{
+				TTL: s.DefaultDuration,
+			},
+		},
+	}
+}
+
+// LeaseExtend returns a callback suitable for Secret.Renew that extends the
+// lease of a secret by the given increment, capped at max (0 means no cap).
+func LeaseExtend(increment, max time.Duration, onlyWithinMax bool) func(*logical.Request, *FieldData) (*logical.Response, error) {
+	return func(req *logical.Request, d *FieldData) (*logical.Response, error) {
+		lease := increment
+		if lease <= 0 {
+			lease = req.Secret.LeaseOptions.Increment
+		}
+		if max > 0 && lease > max {
+			lease = max
+		}
+
+		req.Secret.TTL = lease
+		return &logical.Response{Secret: req.Secret}, nil
+	}
+}
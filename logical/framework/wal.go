@@ -0,0 +1,101 @@
+package framework
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/vault/logical"
+)
+
+const walPrefix = "wal/"
+
+// WALEntry is the storage representation of a single write-ahead-log entry
+// tracked by a backend so that RollbackOperation can later undo partially
+// completed work.
+type WALEntry struct {
+	ID          string      `json:"id"`
+	Kind        string      `json:"kind"`
+	Data        interface{} `json:"data"`
+	CreatedTime time.Time   `json:"created_time"`
+
+	// Attempts and NextRetryTime track the retry/backoff state for this
+	// entry across rollback sweeps, so that a transiently failing entry is
+	// retried with backoff instead of either looping tightly or being
+	// dropped. See Backend.handleRollback.
+	Attempts      int       `json:"attempts"`
+	NextRetryTime time.Time `json:"next_retry_time"`
+}
+
+// PutWAL writes a WAL entry, returning the newly generated ID.
+func PutWAL(s logical.Storage, kind string, data interface{}) (string, error) {
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return "", err
+	}
+
+	entry := &WALEntry{
+		ID:          id,
+		Kind:        kind,
+		Data:        data,
+		CreatedTime: time.Now(),
+	}
+
+	raw, err := logical.StorageEntryJSON(walPrefix+id, entry)
+	if err != nil {
+		return "", err
+	}
+	if err := s.Put(raw); err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+// GetWAL reads a single WAL entry by ID. A nil entry is returned if it
+// doesn't exist (e.g. it has already been rolled back).
+func GetWAL(s logical.Storage, id string) (*WALEntry, error) {
+	raw, err := s.Get(walPrefix + id)
+	if err != nil {
+		return nil, err
+	}
+	if raw == nil {
+		return nil, nil
+	}
+
+	var entry WALEntry
+	if err := raw.DecodeJSON(&entry); err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// putWALEntry writes back an existing WAL entry, e.g. to persist an updated
+// attempt count after a failed rollback.
+func putWALEntry(s logical.Storage, entry *WALEntry) error {
+	raw, err := logical.StorageEntryJSON(walPrefix+entry.ID, entry)
+	if err != nil {
+		return err
+	}
+
+	return s.Put(raw)
+}
+
+// DeleteWAL removes a WAL entry, e.g. once it has been rolled back
+// successfully.
+func DeleteWAL(s logical.Storage, id string) error {
+	return s.Delete(walPrefix + id)
+}
+
+// ListWAL returns the IDs of all stored WAL entries. This backs the
+// sys/wal style read-only listing helper on Backend so operators can
+// inspect entries that are stuck retrying.
+func ListWAL(s logical.Storage) ([]string, error) {
+	keys, err := s.List(walPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("error listing WAL entries: %s", err)
+	}
+
+	return keys, nil
+}